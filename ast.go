@@ -0,0 +1,83 @@
+package go_rsql_parser
+
+// Node is the common interface implemented by every node of an RSQL AST.
+type Node interface {
+	node()
+}
+
+// AndNode represents the conjunction of two RSQL expressions (";" or "and").
+type AndNode struct {
+	Left  Node
+	Right Node
+}
+
+// OrNode represents the disjunction of two RSQL expressions ("," or "or").
+type OrNode struct {
+	Left  Node
+	Right Node
+}
+
+// ComparisonNode represents a single "field op value(s)" comparison, e.g.
+// "age==gt==30" or "name==in==(John,Jane)". Values holds the raw RSQL
+// literals; Coerced holds the same values typed according to a Schema
+// (nil until a Schema has processed the node, in which case dialects
+// compile Coerced instead of Values).
+type ComparisonNode struct {
+	Field   string
+	Op      string
+	Values  []string
+	Coerced []any
+}
+
+func (*AndNode) node()        {}
+func (*OrNode) node()         {}
+func (*ComparisonNode) node() {}
+
+// values returns c's values in their dialect-ready form: the Schema-
+// coerced values (as []any) if a Schema has processed the node,
+// otherwise the raw string literals (as []string, preserving the
+// pre-Schema []string shape callers/tests already depend on).
+func (c *ComparisonNode) values() any {
+	if c.Coerced != nil {
+		return c.Coerced
+	}
+	return c.Values
+}
+
+// value returns c's first value in dialect-ready form (see values), or
+// "" if c has none.
+func (c *ComparisonNode) value() any {
+	if c.Coerced != nil {
+		if len(c.Coerced) == 0 {
+			return ""
+		}
+		return c.Coerced[0]
+	}
+	if len(c.Values) == 0 {
+		return ""
+	}
+	return c.Values[0]
+}
+
+// stringValue returns c's first raw string literal, regardless of any
+// Schema coercion. Used by operators such as "like"/"ilike" that are
+// always string-shaped.
+func stringValue(c *ComparisonNode) string {
+	if len(c.Values) == 0 {
+		return ""
+	}
+	return c.Values[0]
+}
+
+// validOperators is the list of valid RSQL comparison operators.
+var validOperators = [11]string{"==", "eq", "ne", "gt", "ge", "lt", "le", "in", "out", "like", "ilike"}
+
+// isValidOperator checks if the given operator is a valid RSQL operator.
+func isValidOperator(operator string) bool {
+	for _, validOperator := range validOperators {
+		if operator == validOperator {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package go_rsql_parser
+
+// Dialect compiles an RSQL AST into a backend-native query. The second
+// return value holds positional arguments the backend expects to bind
+// alongside the query (e.g. "?" placeholders for SQL); dialects that
+// embed values directly into the native query, such as MongoDB or
+// Elasticsearch, return a nil slice.
+type Dialect interface {
+	Compile(ast Node) (any, []any, error)
+}
+
+// dialects holds the registry of Dialect implementations, keyed by the
+// name passed to ParseRSQL/Compile as dbType.
+var dialects = map[string]Dialect{}
+
+// RegisterDialect registers a Dialect under the given name, making it
+// available to ParseRSQL and Compile. Registering the same name twice
+// overwrites the previous registration, allowing third parties to
+// override the built-in dialects as well as add their own.
+func RegisterDialect(name string, d Dialect) {
+	dialects[name] = d
+}
+
+func init() {
+	RegisterDialect(MongoDB, &mongoDialect{})
+	RegisterDialect(SQL, &sqlDialect{})
+	RegisterDialect(Elasticsearch, &elasticsearchDialect{})
+}
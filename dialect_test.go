@@ -0,0 +1,115 @@
+package go_rsql_parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestDialects runs the same RSQL query through every registered dialect
+// and asserts each backend's native query shape.
+func TestDialects(t *testing.T) {
+	t.Run("TestCompileMongo", testCompileMongo)
+	t.Run("TestCompileSQL", testCompileSQL)
+	t.Run("TestCompileElasticsearch", testCompileElasticsearch)
+	t.Run("TestCompileUnregisteredDialect", testCompileUnregisteredDialect)
+	t.Run("TestCompileSQLRejectsUnsafeFieldName", testCompileSQLRejectsUnsafeFieldName)
+}
+
+// testCompileMongo tests compiling a grouped RSQL query into a MongoDB filter.
+func testCompileMongo(t *testing.T) {
+	query := "(a==1,b==2);c==gt==3"
+	native, args, err := Compile(query, MongoDB)
+	if err != nil {
+		t.Fatalf("Error compiling RSQL query: %s", err)
+	}
+	if args != nil {
+		t.Errorf("Expected no positional args for the MongoDB dialect, got %v", args)
+	}
+	expected := bson.M{
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"a": bson.M{"$eq": "1"}},
+				{"b": bson.M{"$eq": "2"}},
+			}},
+			{"c": bson.M{"$gt": "3"}},
+		},
+	}
+	if !compareMaps(native.(bson.M), expected) {
+		t.Errorf("Compiled MongoDB query does not match expected query")
+	}
+}
+
+// testCompileSQL tests compiling a grouped RSQL query into a squirrel.Sqlizer.
+func testCompileSQL(t *testing.T) {
+	query := "(a==1,b==2);c==gt==3"
+	native, args, err := Compile(query, SQL)
+	if err != nil {
+		t.Fatalf("Error compiling RSQL query: %s", err)
+	}
+	sqlizer, ok := native.(squirrel.Sqlizer)
+	if !ok {
+		t.Fatalf("Expected a squirrel.Sqlizer, got %T", native)
+	}
+	sql, sqlArgs, err := sqlizer.ToSql()
+	if err != nil {
+		t.Fatalf("Error building SQL: %s", err)
+	}
+	if sql == "" {
+		t.Errorf("Expected a non-empty SQL predicate")
+	}
+	if !reflect.DeepEqual(args, sqlArgs) {
+		t.Errorf("Expected Compile's args to match the Sqlizer's own args")
+	}
+	if len(sqlArgs) != 3 {
+		t.Errorf("Expected 3 positional args, got %d", len(sqlArgs))
+	}
+}
+
+// testCompileElasticsearch tests compiling a grouped RSQL query into an Elasticsearch DSL document.
+func testCompileElasticsearch(t *testing.T) {
+	query := "(a==1,b==2);c==gt==3"
+	native, _, err := Compile(query, Elasticsearch)
+	if err != nil {
+		t.Fatalf("Error compiling RSQL query: %s", err)
+	}
+	doc, ok := native.(map[string]any)
+	if !ok {
+		t.Fatalf("Expected a map[string]any, got %T", native)
+	}
+	boolQuery, ok := doc["bool"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected a top-level \"bool\" clause, got %v", doc)
+	}
+	must, ok := boolQuery["must"].([]map[string]any)
+	if !ok || len(must) != 2 {
+		t.Fatalf("Expected 2 \"must\" clauses, got %v", boolQuery["must"])
+	}
+	if _, ok := must[0]["bool"]; !ok {
+		t.Errorf("Expected the first \"must\" clause to be a nested \"bool\"/\"should\" query")
+	}
+	if _, ok := must[1]["range"]; !ok {
+		t.Errorf("Expected the second \"must\" clause to be a \"range\" query")
+	}
+}
+
+// testCompileSQLRejectsUnsafeFieldName tests that the SQL dialect refuses to
+// compile a field name containing characters outside a plain identifier,
+// since squirrel interpolates map keys into the SQL text unescaped.
+func testCompileSQLRejectsUnsafeFieldName(t *testing.T) {
+	query := "id OR 1=1 --==x"
+	_, _, err := Compile(query, SQL)
+	if err == nil {
+		t.Errorf("Expected an error for an unsafe SQL field name")
+	}
+}
+
+// testCompileUnregisteredDialect tests compiling against a dbType with no registered dialect.
+func testCompileUnregisteredDialect(t *testing.T) {
+	_, _, err := Compile("a==1", "oracle")
+	if err == nil {
+		t.Errorf("Expected an error for an unregistered dialect")
+	}
+}
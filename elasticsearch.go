@@ -0,0 +1,103 @@
+package go_rsql_parser
+
+import "errors"
+
+// elasticsearchDialect compiles an RSQL AST into an Elasticsearch Query
+// DSL document.
+type elasticsearchDialect struct{}
+
+func (elasticsearchDialect) Compile(ast Node) (any, []any, error) {
+	query, err := toElasticsearch(ast)
+	if err != nil {
+		return nil, nil, err
+	}
+	return query, nil, nil
+}
+
+// toElasticsearch walks an RSQL AST, translating it into an
+// Elasticsearch "bool" query. AndNode/OrNode chains are flattened so
+// that chained conjunctions/disjunctions produce a single "must"/
+// "should" array instead of nested bool queries.
+func toElasticsearch(n Node) (map[string]any, error) {
+	switch v := n.(type) {
+	case *ComparisonNode:
+		return comparisonToElasticsearch(v)
+	case *RangeNode:
+		return rangeToElasticsearch(v), nil
+	case *MatchNone:
+		return map[string]any{"bool": map[string]any{"must_not": map[string]any{"match_all": map[string]any{}}}}, nil
+	case *AndNode:
+		clauses, err := elasticsearchClauses(flattenAnd(v))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"bool": map[string]any{"must": clauses}}, nil
+	case *OrNode:
+		clauses, err := elasticsearchClauses(flattenOr(v))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"bool": map[string]any{"should": clauses, "minimum_should_match": 1}}, nil
+	default:
+		return nil, errors.New("rsql: unsupported AST node")
+	}
+}
+
+func elasticsearchClauses(nodes []Node) ([]map[string]any, error) {
+	clauses := make([]map[string]any, 0, len(nodes))
+	for _, node := range nodes {
+		clause, err := toElasticsearch(node)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, nil
+}
+
+// rangeToElasticsearch converts a RangeNode into a single "range" query
+// combining its lower and upper bounds. Bound values are rendered via
+// boundValue, the same Schema-coerced-or-raw-string representation a
+// lone (unmerged) gt/ge/lt/le ComparisonNode carries, so a query's
+// value type doesn't depend on whether two bounds happened to merge.
+func rangeToElasticsearch(r *RangeNode) map[string]any {
+	bounds := map[string]any{}
+	if r.Min != nil {
+		bounds[boundOp(r.Min, "gt", "gte")] = boundValue(r.Min)
+	}
+	if r.Max != nil {
+		bounds[boundOp(r.Max, "lt", "lte")] = boundValue(r.Max)
+	}
+	return map[string]any{"range": map[string]any{r.Field: bounds}}
+}
+
+// comparisonToElasticsearch converts a single ComparisonNode into an
+// Elasticsearch leaf (or bool) query clause.
+func comparisonToElasticsearch(c *ComparisonNode) (map[string]any, error) {
+	switch c.Op {
+	case "==", "eq":
+		return map[string]any{"term": map[string]any{c.Field: c.value()}}, nil
+	case "ne":
+		return map[string]any{"bool": map[string]any{
+			"must_not": []map[string]any{{"term": map[string]any{c.Field: c.value()}}},
+		}}, nil
+	case "gt":
+		return map[string]any{"range": map[string]any{c.Field: map[string]any{"gt": c.value()}}}, nil
+	case "ge":
+		return map[string]any{"range": map[string]any{c.Field: map[string]any{"gte": c.value()}}}, nil
+	case "lt":
+		return map[string]any{"range": map[string]any{c.Field: map[string]any{"lt": c.value()}}}, nil
+	case "le":
+		return map[string]any{"range": map[string]any{c.Field: map[string]any{"lte": c.value()}}}, nil
+	case "in":
+		return map[string]any{"terms": map[string]any{c.Field: c.values()}}, nil
+	case "out":
+		return map[string]any{"bool": map[string]any{
+			"must_not": []map[string]any{{"terms": map[string]any{c.Field: c.values()}}},
+		}}, nil
+	case "like", "ilike":
+		return map[string]any{"wildcard": map[string]any{c.Field: map[string]any{"value": "*" + stringValue(c) + "*"}}}, nil
+	default:
+		return nil, errors.New("invalid operator: " + c.Op)
+	}
+}
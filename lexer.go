@@ -0,0 +1,319 @@
+package go_rsql_parser
+
+import (
+	"errors"
+	"strings"
+)
+
+// tokenKind identifies the grammatical category of a lexed token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokComparison
+)
+
+// token is a single lexical unit produced by the lexer. comp is only
+// populated when kind is tokComparison.
+type token struct {
+	kind tokenKind
+	comp *ComparisonNode
+}
+
+// lexer tokenizes an RSQL query string, handling grouping, the ";"/"and"
+// and ","/"or" logical separators, quoted values (with "\" escapes) and
+// "(a,b,c)" value lists for in/out comparisons.
+type lexer struct {
+	input string
+	pos   int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: input}
+}
+
+func (l *lexer) skipWhitespace() {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// next returns the next token in the stream.
+func (l *lexer) next() (token, error) {
+	l.skipWhitespace()
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF}, nil
+	}
+
+	switch l.input[l.pos] {
+	case '(':
+		l.pos++
+		return token{kind: tokLParen}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokRParen}, nil
+	case ';':
+		l.pos++
+		return token{kind: tokAnd}, nil
+	case ',':
+		l.pos++
+		return token{kind: tokOr}, nil
+	}
+
+	if word, ok := l.peekWord(); ok {
+		if word == "and" {
+			l.pos += len(word)
+			return token{kind: tokAnd}, nil
+		}
+		if word == "or" {
+			l.pos += len(word)
+			return token{kind: tokOr}, nil
+		}
+	}
+
+	return l.lexComparison()
+}
+
+// peekWord returns a bare keyword ("and"/"or") starting at the current
+// position, if the current position begins with one followed by a
+// word boundary.
+func (l *lexer) peekWord() (string, bool) {
+	for _, word := range []string{"and", "or"} {
+		end := l.pos + len(word)
+		if end <= len(l.input) && l.input[l.pos:end] == word {
+			if end == len(l.input) || isBoundary(l.input[end]) {
+				return word, true
+			}
+		}
+	}
+	return "", false
+}
+
+func isBoundary(b byte) bool {
+	return isSpace(b) || b == '(' || b == ')' || b == ';' || b == ','
+}
+
+// lexComparison reads a "field==value" or "field==op==value" segment.
+func (l *lexer) lexComparison() (token, error) {
+	field, err := l.readBareSegment()
+	if err != nil {
+		return token{}, err
+	}
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return token{}, errors.New("rsql: expected field name")
+	}
+
+	if !l.consumeLiteral("==") {
+		return token{}, errors.New("rsql: expected '==' after field '" + field + "'")
+	}
+
+	op := "=="
+	var values []string
+
+	var isList bool
+
+	l.skipWhitespace()
+	if l.pos < len(l.input) && (l.input[l.pos] == '\'' || l.input[l.pos] == '"' || l.input[l.pos] == '(') {
+		// A quoted literal or value list can only appear as the final
+		// value, so this is the 2-part "field==value" form.
+		v, list, err := l.readValueSegment()
+		if err != nil {
+			return token{}, err
+		}
+		values, isList = v, list
+	} else {
+		middle, err := l.readMiddleToken()
+		if err != nil {
+			return token{}, err
+		}
+		if l.consumeLiteral("==") {
+			op = middle
+			values, isList, err = l.readValueSegment()
+			if err != nil {
+				return token{}, err
+			}
+		} else {
+			values = []string{middle}
+		}
+	}
+
+	if !isValidOperator(op) {
+		return token{}, errors.New("invalid operator: " + op)
+	}
+	if isList && op != "in" && op != "out" {
+		return token{}, errors.New("rsql: value list is only valid with the 'in'/'out' operators, got '" + op + "'")
+	}
+
+	return token{kind: tokComparison, comp: &ComparisonNode{Field: field, Op: op, Values: values}}, nil
+}
+
+// readMiddleToken reads the segment right after a field's leading "==",
+// which may turn out to be either an operator name (if followed by a
+// further "==") or the comparison's literal value. It stops at the next
+// "==" as well as at the usual value terminators.
+func (l *lexer) readMiddleToken() (string, error) {
+	start := l.pos
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if c == '=' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '=' {
+			break
+		}
+		if c == ';' || c == ',' || c == ')' || c == '(' {
+			break
+		}
+		if word, ok := l.peekWord(); ok && (word == "and" || word == "or") {
+			break
+		}
+		l.pos++
+	}
+	return strings.TrimSpace(l.input[start:l.pos]), nil
+}
+
+func (l *lexer) consumeLiteral(lit string) bool {
+	end := l.pos + len(lit)
+	if end <= len(l.input) && l.input[l.pos:end] == lit {
+		l.pos = end
+		return true
+	}
+	return false
+}
+
+// readBareSegment reads characters up to (but not including) the next
+// "==", returning them as a single-element slice for reuse by
+// readValueSegment's callers.
+func (l *lexer) readBareSegment() (string, error) {
+	start := l.pos
+	for l.pos < len(l.input) {
+		if l.input[l.pos] == '=' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '=' {
+			break
+		}
+		l.pos++
+	}
+	return l.input[start:l.pos], nil
+}
+
+// readValueSegment reads a single value position: a quoted literal, a
+// parenthesized "(a,b,c)" list, or a bare token. It returns the parsed
+// value(s) and whether they came from a "(...)" list, so callers can
+// reject lists on operators other than "in"/"out".
+func (l *lexer) readValueSegment() ([]string, bool, error) {
+	l.skipWhitespace()
+	if l.pos >= len(l.input) {
+		return nil, false, errors.New("rsql: expected value")
+	}
+
+	switch l.input[l.pos] {
+	case '\'', '"':
+		v, err := l.readQuoted(l.input[l.pos])
+		if err != nil {
+			return nil, false, err
+		}
+		return []string{v}, false, nil
+	case '(':
+		v, err := l.readList()
+		if err != nil {
+			return nil, false, err
+		}
+		return v, true, nil
+	default:
+		v, err := l.readBareValue()
+		if err != nil {
+			return nil, false, err
+		}
+		return []string{v}, false, nil
+	}
+}
+
+// readQuoted reads a quote-delimited string, honoring "\" escapes, and
+// consumes the closing quote.
+func (l *lexer) readQuoted(quote byte) (string, error) {
+	l.pos++ // opening quote
+	var sb strings.Builder
+	for {
+		if l.pos >= len(l.input) {
+			return "", errors.New("rsql: unterminated quoted value")
+		}
+		c := l.input[l.pos]
+		if c == '\\' && l.pos+1 < len(l.input) {
+			sb.WriteByte(l.input[l.pos+1])
+			l.pos += 2
+			continue
+		}
+		if c == quote {
+			l.pos++
+			return sb.String(), nil
+		}
+		sb.WriteByte(c)
+		l.pos++
+	}
+}
+
+// readBareValue reads an unquoted value, up to the next structural
+// character (";", ",", ")") or a bare "and"/"or" keyword, trimming
+// trailing whitespace.
+func (l *lexer) readBareValue() (string, error) {
+	start := l.pos
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if c == ';' || c == ',' || c == ')' || c == '(' {
+			break
+		}
+		if word, ok := l.peekWord(); ok && (word == "and" || word == "or") {
+			break
+		}
+		l.pos++
+	}
+	return strings.TrimRight(l.input[start:l.pos], " \t\r\n"), nil
+}
+
+// readList reads a "(a,b,c)" value list, supporting quoted elements
+// with "\" escapes, and consumes the closing parenthesis.
+func (l *lexer) readList() ([]string, error) {
+	l.pos++ // opening paren
+	var values []string
+	for {
+		l.skipWhitespace()
+		if l.pos >= len(l.input) {
+			return nil, errors.New("rsql: unterminated value list")
+		}
+		if l.input[l.pos] == ')' {
+			l.pos++
+			return values, nil
+		}
+		var v string
+		var err error
+		if l.input[l.pos] == '\'' || l.input[l.pos] == '"' {
+			v, err = l.readQuoted(l.input[l.pos])
+		} else {
+			v, err = l.readListElement()
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		l.skipWhitespace()
+		if l.pos < len(l.input) && l.input[l.pos] == ',' {
+			l.pos++
+			continue
+		}
+	}
+}
+
+// readListElement reads a single unquoted element of a value list, up
+// to the next "," or ")".
+func (l *lexer) readListElement() (string, error) {
+	start := l.pos
+	for l.pos < len(l.input) && l.input[l.pos] != ',' && l.input[l.pos] != ')' {
+		l.pos++
+	}
+	return strings.TrimSpace(l.input[start:l.pos]), nil
+}
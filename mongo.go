@@ -0,0 +1,136 @@
+package go_rsql_parser
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// mongoDialect compiles an RSQL AST into a MongoDB query via ConvertToMongo.
+type mongoDialect struct{}
+
+func (mongoDialect) Compile(ast Node) (any, []any, error) {
+	query, err := ConvertToMongo(ast)
+	if err != nil {
+		return nil, nil, err
+	}
+	return query, nil, nil
+}
+
+// ConvertToMongo walks an RSQL AST and compiles it into a MongoDB query
+// filter. AndNode and OrNode are flattened into "$and"/"$or" arrays so
+// that chained conjunctions/disjunctions produce a single array rather
+// than nested pairs.
+func ConvertToMongo(n Node) (bson.M, error) {
+	switch v := n.(type) {
+	case *ComparisonNode:
+		return comparisonToMongo(v)
+	case *RangeNode:
+		return rangeToMongo(v), nil
+	case *MatchNone:
+		return bson.M{"_id": bson.M{"$in": bson.A{}}}, nil
+	case *AndNode:
+		clauses, err := convertAll(flattenAnd(v))
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$and": clauses}, nil
+	case *OrNode:
+		clauses, err := convertAll(flattenOr(v))
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$or": clauses}, nil
+	default:
+		return nil, errors.New("rsql: unsupported AST node")
+	}
+}
+
+// rangeToMongo converts a RangeNode into a single field filter
+// combining its lower and upper bounds. Bound values are rendered via
+// boundValue, the same Schema-coerced-or-raw-string representation a
+// lone (unmerged) gt/ge/lt/le ComparisonNode carries, so a filter's
+// value type doesn't depend on whether two bounds happened to merge.
+func rangeToMongo(r *RangeNode) bson.M {
+	bounds := bson.M{}
+	if r.Min != nil {
+		bounds[boundOp(r.Min, "$gt", "$gte")] = boundValue(r.Min)
+	}
+	if r.Max != nil {
+		bounds[boundOp(r.Max, "$lt", "$lte")] = boundValue(r.Max)
+	}
+	return bson.M{r.Field: bounds}
+}
+
+func convertAll(nodes []Node) ([]bson.M, error) {
+	clauses := make([]bson.M, 0, len(nodes))
+	for _, node := range nodes {
+		clause, err := ConvertToMongo(node)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, nil
+}
+
+// flattenAnd unwraps a chain of AndNode values into a flat operand list.
+func flattenAnd(n *AndNode) []Node {
+	var nodes []Node
+	var walk func(node Node)
+	walk = func(node Node) {
+		if and, ok := node.(*AndNode); ok {
+			walk(and.Left)
+			walk(and.Right)
+			return
+		}
+		nodes = append(nodes, node)
+	}
+	walk(n)
+	return nodes
+}
+
+// flattenOr unwraps a chain of OrNode values into a flat operand list.
+func flattenOr(n *OrNode) []Node {
+	var nodes []Node
+	var walk func(node Node)
+	walk = func(node Node) {
+		if or, ok := node.(*OrNode); ok {
+			walk(or.Left)
+			walk(or.Right)
+			return
+		}
+		nodes = append(nodes, node)
+	}
+	walk(n)
+	return nodes
+}
+
+// comparisonToMongo converts a single ComparisonNode into a MongoDB
+// field filter.
+func comparisonToMongo(c *ComparisonNode) (bson.M, error) {
+	switch c.Op {
+	case "==", "eq":
+		return bson.M{c.Field: bson.M{"$eq": c.value()}}, nil
+	case "ne":
+		return bson.M{c.Field: bson.M{"$ne": c.value()}}, nil
+	case "gt":
+		return bson.M{c.Field: bson.M{"$gt": c.value()}}, nil
+	case "ge":
+		return bson.M{c.Field: bson.M{"$gte": c.value()}}, nil
+	case "lt":
+		return bson.M{c.Field: bson.M{"$lt": c.value()}}, nil
+	case "le":
+		return bson.M{c.Field: bson.M{"$lte": c.value()}}, nil
+	case "in":
+		return bson.M{c.Field: bson.M{"$in": c.values()}}, nil
+	case "out":
+		return bson.M{c.Field: bson.M{"$nin": c.values()}}, nil
+	case "like":
+		return bson.M{c.Field: bson.M{"$regex": stringValue(c)}}, nil
+	case "ilike":
+		return bson.M{c.Field: bson.M{"$regex": "(?i)" + stringValue(c)}}, nil
+	default:
+		return nil, errors.New("invalid operator: " + c.Op)
+	}
+}
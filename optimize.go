@@ -0,0 +1,591 @@
+package go_rsql_parser
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrEmptyResult is returned by Optimize when it can prove a query can
+// never match any document, e.g. "age==eq==1;age==eq==2" or
+// "age==gt==10;age==lt==5".
+var ErrEmptyResult = errors.New("rsql: query can never match any document")
+
+// MatchNone is a sentinel AST node produced internally by Optimize for a
+// provably-empty query. Dialects render it as an always-false filter
+// (the MongoDB dialect renders it as {"_id": {"$in": []}}). Optimize
+// itself never returns a MatchNone to its caller — it returns
+// ErrEmptyResult instead — but dialects may still encounter one if a
+// MatchNone is embedded directly in a hand-built AST.
+type MatchNone struct{}
+
+func (*MatchNone) node() {}
+
+// RangeNode represents a single field constrained by both a lower and
+// an upper bound, produced by Optimize when it merges two complementary
+// comparisons on the same field (e.g. "age==gt==10;age==lt==20").
+type RangeNode struct {
+	Field string
+	Min   *Bound
+	Max   *Bound
+}
+
+func (*RangeNode) node() {}
+
+// Bound is one side (lower or upper) of a RangeNode.
+type Bound struct {
+	Value     float64
+	Inclusive bool
+	// Raw is the original RSQL literal the bound's Value was parsed
+	// from. It's kept verbatim (rather than reformatted from Value)
+	// because strconv.FormatFloat's 'g' format switches to scientific
+	// notation for large/small magnitudes (e.g. 2000000 -> "2e+06"),
+	// which a Schema's strconv.Atoi can't parse back.
+	Raw string
+	// Coerced is the Schema-coerced scalar the originating comparison
+	// carried, if a Schema ran before Optimize; nil otherwise, in which
+	// case dialects fall back to Raw.
+	Coerced any
+}
+
+// Optimize simplifies an RSQL AST: it merges multiple comparisons on
+// the same field under an And (tightening gt/ge/lt/le into a single
+// range, intersecting repeated "in" lists, unioning repeated "out"
+// lists), folds duplicate Or operands and absorbs "A or (A and B)"
+// into "A", and proves when a query can never match, returning
+// ErrEmptyResult in that case.
+func Optimize(n Node) (Node, error) {
+	optimized, err := optimize(n)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := optimized.(*MatchNone); ok {
+		return nil, ErrEmptyResult
+	}
+	return optimized, nil
+}
+
+func optimize(n Node) (Node, error) {
+	switch v := n.(type) {
+	case *AndNode:
+		return optimizeAnd(v)
+	case *OrNode:
+		return optimizeOr(v)
+	default:
+		return n, nil
+	}
+}
+
+func optimizeAnd(n *AndNode) (Node, error) {
+	operands := make([]Node, 0)
+	for _, raw := range flattenAnd(n) {
+		optimized, err := optimize(raw)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := optimized.(*MatchNone); ok {
+			return &MatchNone{}, nil
+		}
+		operands = append(operands, optimized)
+	}
+
+	byField := map[string][]*ComparisonNode{}
+	var fieldOrder []string
+	var passthrough []Node
+
+	for _, operand := range operands {
+		if cmp, ok := operand.(*ComparisonNode); ok {
+			if _, seen := byField[cmp.Field]; !seen {
+				fieldOrder = append(fieldOrder, cmp.Field)
+			}
+			byField[cmp.Field] = append(byField[cmp.Field], cmp)
+			continue
+		}
+		passthrough = append(passthrough, operand)
+	}
+
+	merged := make([]Node, 0, len(operands))
+	for _, field := range fieldOrder {
+		nodes, empty, err := mergeFieldComparisons(field, byField[field])
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			return &MatchNone{}, nil
+		}
+		merged = append(merged, nodes...)
+	}
+	merged = append(merged, passthrough...)
+	merged = dedupeNodes(merged)
+
+	return buildAnd(merged), nil
+}
+
+func optimizeOr(n *OrNode) (Node, error) {
+	operands := make([]Node, 0)
+	for _, raw := range flattenOr(n) {
+		optimized, err := optimize(raw)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := optimized.(*MatchNone); ok {
+			// A disjunct that can never match never changes the result
+			// of the Or, so it's simply dropped.
+			continue
+		}
+		operands = append(operands, optimized)
+	}
+	if len(operands) == 0 {
+		return &MatchNone{}, nil
+	}
+
+	operands = dedupeNodes(operands)
+	operands = absorbOrOperands(operands)
+
+	return buildOr(operands), nil
+}
+
+// absorbOrOperands applies "A or (A and B)" -> "A": if one operand's
+// conjuncts are a superset of another operand's conjuncts, the superset
+// operand is redundant and is dropped.
+func absorbOrOperands(operands []Node) []Node {
+	conjunctKeys := make([][]string, len(operands))
+	for i, operand := range operands {
+		conjunctKeys[i] = sortedKeys(flattenAnd2(operand))
+	}
+
+	keep := make([]bool, len(operands))
+	for i := range operands {
+		keep[i] = true
+	}
+	for i := range operands {
+		if !keep[i] {
+			continue
+		}
+		for j := range operands {
+			if i == j || !keep[j] {
+				continue
+			}
+			if isSupersetOf(conjunctKeys[j], conjunctKeys[i]) {
+				keep[j] = false
+			}
+		}
+	}
+
+	result := make([]Node, 0, len(operands))
+	for i, operand := range operands {
+		if keep[i] {
+			result = append(result, operand)
+		}
+	}
+	return result
+}
+
+// flattenAnd2 returns n's conjuncts if n is an AndNode, or []Node{n}
+// otherwise, so callers can treat every Or operand uniformly.
+func flattenAnd2(n Node) []Node {
+	if and, ok := n.(*AndNode); ok {
+		return flattenAnd(and)
+	}
+	return []Node{n}
+}
+
+// isSupersetOf reports whether every key in subsetKeys also appears in
+// supersetKeys (both must be sorted).
+func isSupersetOf(supersetKeys, subsetKeys []string) bool {
+	if len(supersetKeys) <= len(subsetKeys) {
+		return false
+	}
+	set := make(map[string]bool, len(supersetKeys))
+	for _, k := range supersetKeys {
+		set[k] = true
+	}
+	for _, k := range subsetKeys {
+		if !set[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(nodes []Node) []string {
+	keys := make([]string, len(nodes))
+	for i, n := range nodes {
+		keys[i] = nodeKey(n)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func buildAnd(nodes []Node) Node {
+	result := nodes[0]
+	for _, n := range nodes[1:] {
+		result = &AndNode{Left: result, Right: n}
+	}
+	return result
+}
+
+func buildOr(nodes []Node) Node {
+	result := nodes[0]
+	for _, n := range nodes[1:] {
+		result = &OrNode{Left: result, Right: n}
+	}
+	return result
+}
+
+// dedupeNodes removes structurally identical nodes, preserving order.
+func dedupeNodes(nodes []Node) []Node {
+	seen := make(map[string]bool, len(nodes))
+	result := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		key := nodeKey(n)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, n)
+	}
+	return result
+}
+
+// nodeKey returns a canonical string identifying a node's shape, used
+// to detect duplicates and Or/And absorption candidates. And/Or nodes
+// are canonicalized via their sorted, flattened operand keys so that
+// operand order never affects equality.
+func nodeKey(n Node) string {
+	switch v := n.(type) {
+	case *ComparisonNode:
+		return "cmp:" + v.Field + ":" + v.Op + ":" + strings.Join(v.Values, ",")
+	case *RangeNode:
+		return "range:" + v.Field + ":" + boundKey(v.Min) + ":" + boundKey(v.Max)
+	case *MatchNone:
+		return "none"
+	case *AndNode:
+		return "and[" + strings.Join(sortedKeys(flattenAnd(v)), "|") + "]"
+	case *OrNode:
+		return "or[" + strings.Join(sortedKeys(flattenOr(v)), "|") + "]"
+	default:
+		return ""
+	}
+}
+
+func boundKey(b *Bound) string {
+	if b == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(b.Value, 'g', -1, 64) + "/" + strconv.FormatBool(b.Inclusive)
+}
+
+// mergeFieldComparisons merges every comparison targeting the same
+// field into the fewest equivalent nodes, or reports that the
+// combination can never match.
+func mergeFieldComparisons(field string, comps []*ComparisonNode) ([]Node, bool, error) {
+	var eqValue *string
+	var eqCoerced any
+	var lower, upper *Bound
+	var inSets [][]string
+	var outValues []string
+	var passthrough []Node
+	boundsNumeric := true
+	coercions := valueCoercions(comps)
+
+	for _, c := range comps {
+		switch c.Op {
+		case "gt", "ge", "lt", "le":
+			if _, err := strconv.ParseFloat(stringValue(c), 64); err != nil {
+				boundsNumeric = false
+			}
+		}
+	}
+
+	for _, c := range comps {
+		switch c.Op {
+		case "==", "eq":
+			v := stringValue(c)
+			if eqValue != nil && *eqValue != v {
+				return nil, true, nil
+			}
+			eqValue = &v
+			eqCoerced = coercedValue(c)
+		case "gt", "ge", "lt", "le":
+			if !boundsNumeric {
+				passthrough = append(passthrough, c)
+				continue
+			}
+			raw := stringValue(c)
+			f, _ := strconv.ParseFloat(raw, 64)
+			inclusive := c.Op == "ge" || c.Op == "le"
+			bound := &Bound{Value: f, Inclusive: inclusive, Raw: raw, Coerced: coercedValue(c)}
+			if c.Op == "gt" || c.Op == "ge" {
+				lower = tighterLower(lower, bound)
+			} else {
+				upper = tighterUpper(upper, bound)
+			}
+		case "in":
+			inSets = append(inSets, c.Values)
+		case "out":
+			outValues = append(outValues, c.Values...)
+		default:
+			passthrough = append(passthrough, c)
+		}
+	}
+
+	if eqValue != nil {
+		return mergeWithEquality(field, *eqValue, eqCoerced, lower, upper, inSets, outValues, passthrough)
+	}
+
+	var result []Node
+
+	if len(inSets) > 0 {
+		values := intersectAll(inSets)
+		values = subtractValues(values, outValues)
+		if len(values) == 0 {
+			return nil, true, nil
+		}
+		result = append(result, &ComparisonNode{Field: field, Op: "in", Values: values, Coerced: coercedFor(values, coercions)})
+	} else if len(outValues) > 0 {
+		deduped := dedupeStrings(outValues)
+		result = append(result, &ComparisonNode{Field: field, Op: "out", Values: deduped, Coerced: coercedFor(deduped, coercions)})
+	}
+
+	boundNode, contradiction := buildBoundNode(field, lower, upper)
+	if contradiction {
+		return nil, true, nil
+	}
+	if boundNode != nil {
+		result = append(result, boundNode)
+	}
+
+	result = append(result, passthrough...)
+	return result, false, nil
+}
+
+// buildBoundNode folds a field's lower/upper bounds into a single node —
+// a RangeNode if both are present, a lone ComparisonNode if only one is —
+// reporting a contradiction if the bounds can never both hold. Returns a
+// nil node (and no contradiction) if neither bound is present.
+func buildBoundNode(field string, lower, upper *Bound) (Node, bool) {
+	switch {
+	case lower != nil && upper != nil:
+		if lower.Value > upper.Value || (lower.Value == upper.Value && !(lower.Inclusive && upper.Inclusive)) {
+			return nil, true
+		}
+		return &RangeNode{Field: field, Min: lower, Max: upper}, false
+	case lower != nil:
+		return &ComparisonNode{Field: field, Op: boundOp(lower, "gt", "ge"), Values: []string{lower.Raw}, Coerced: coercedSlice(lower.Coerced)}, false
+	case upper != nil:
+		return &ComparisonNode{Field: field, Op: boundOp(upper, "lt", "le"), Values: []string{upper.Raw}, Coerced: coercedSlice(upper.Coerced)}, false
+	default:
+		return nil, false
+	}
+}
+
+// mergeWithEquality folds the remaining constraints on a field into a
+// single equality comparison once an "==" comparison pins it to one
+// value, reporting a contradiction if any other constraint rules that
+// value out. If the equality value isn't numeric, any gt/ge/lt/le bound
+// can't be checked against it, so the bound is kept as a separate
+// conjunct rather than silently dropped.
+func mergeWithEquality(field, value string, coerced any, lower, upper *Bound, inSets [][]string, outValues []string, passthrough []Node) ([]Node, bool, error) {
+	result := []Node{&ComparisonNode{Field: field, Op: "==", Values: []string{value}, Coerced: coercedSlice(coerced)}}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		if lower != nil && (f < lower.Value || (f == lower.Value && !lower.Inclusive)) {
+			return nil, true, nil
+		}
+		if upper != nil && (f > upper.Value || (f == upper.Value && !upper.Inclusive)) {
+			return nil, true, nil
+		}
+	} else {
+		boundNode, contradiction := buildBoundNode(field, lower, upper)
+		if contradiction {
+			return nil, true, nil
+		}
+		if boundNode != nil {
+			result = append(result, boundNode)
+		}
+	}
+
+	for _, set := range inSets {
+		if !containsValue(set, value) {
+			return nil, true, nil
+		}
+	}
+	if containsValue(outValues, value) {
+		return nil, true, nil
+	}
+
+	result = append(result, passthrough...)
+	return result, false, nil
+}
+
+// containsValue reports whether values contains target, treating two
+// values as equal if they're identical strings or parse as the same
+// float (so "10" and "10.0" match).
+func containsValue(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+		fv, errV := strconv.ParseFloat(v, 64)
+		ft, errT := strconv.ParseFloat(target, 64)
+		if errV == nil && errT == nil && fv == ft {
+			return true
+		}
+	}
+	return false
+}
+
+// tighterLower keeps the larger (tighter) of two lower bounds, with an
+// exclusive bound winning a tie against an inclusive one at the same value.
+func tighterLower(current, next *Bound) *Bound {
+	if current == nil {
+		return next
+	}
+	if next.Value != current.Value {
+		if next.Value > current.Value {
+			return next
+		}
+		return current
+	}
+	if !next.Inclusive {
+		return next
+	}
+	return current
+}
+
+// tighterUpper keeps the smaller (tighter) of two upper bounds, with an
+// exclusive bound winning a tie against an inclusive one at the same value.
+func tighterUpper(current, next *Bound) *Bound {
+	if current == nil {
+		return next
+	}
+	if next.Value != current.Value {
+		if next.Value < current.Value {
+			return next
+		}
+		return current
+	}
+	if !next.Inclusive {
+		return next
+	}
+	return current
+}
+
+func boundOp(b *Bound, exclusiveOp, inclusiveOp string) string {
+	if b.Inclusive {
+		return inclusiveOp
+	}
+	return exclusiveOp
+}
+
+// boundValue returns b's dialect-ready value: its Schema-coerced scalar
+// if a Schema ran before Optimize, otherwise its original raw RSQL
+// literal (matching the type a lone, un-Schema'd gt/ge/lt/le
+// ComparisonNode's value() would carry).
+func boundValue(b *Bound) any {
+	if b.Coerced != nil {
+		return b.Coerced
+	}
+	return b.Raw
+}
+
+func intersectAll(sets [][]string) []string {
+	result := sets[0]
+	for _, set := range sets[1:] {
+		result = intersect(result, set)
+	}
+	return dedupeStrings(result)
+}
+
+func intersect(a, b []string) []string {
+	var result []string
+	for _, v := range a {
+		if containsString(b, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func subtractValues(values, exclude []string) []string {
+	if len(exclude) == 0 {
+		return values
+	}
+	var result []string
+	for _, v := range values {
+		if !containsString(exclude, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// coercedValue returns c's single Schema-coerced scalar, or nil if no
+// Schema coerced it (c.Coerced is nil/empty).
+func coercedValue(c *ComparisonNode) any {
+	if len(c.Coerced) == 0 {
+		return nil
+	}
+	return c.Coerced[0]
+}
+
+// coercedSlice wraps a single coerced scalar into the []any shape
+// ComparisonNode.Coerced expects, or returns nil if v is nil so a node
+// with no Schema coercion keeps Coerced nil (falling back to Values).
+func coercedSlice(v any) []any {
+	if v == nil {
+		return nil
+	}
+	return []any{v}
+}
+
+// valueCoercions maps every raw RSQL literal appearing in comps to its
+// Schema-coerced scalar, gathered from whichever comparison carried it.
+// It lets merged "in"/"out" nodes carry a Coerced list like their inputs
+// do, even though the merged value set (after intersecting/subtracting)
+// no longer corresponds to any single input comparison's Values/Coerced
+// pair. Returns an empty map if no comp was Schema-coerced.
+func valueCoercions(comps []*ComparisonNode) map[string]any {
+	coercions := map[string]any{}
+	for _, c := range comps {
+		for i, raw := range c.Values {
+			if i < len(c.Coerced) {
+				coercions[raw] = c.Coerced[i]
+			}
+		}
+	}
+	return coercions
+}
+
+// coercedFor looks up each of values in coercions, returning the
+// parallel []any Coerced slice, or nil if coercions doesn't have an
+// entry for every value (e.g. no Schema ran).
+func coercedFor(values []string, coercions map[string]any) []any {
+	if len(coercions) == 0 {
+		return nil
+	}
+	result := make([]any, len(values))
+	for i, v := range values {
+		c, ok := coercions[v]
+		if !ok {
+			return nil
+		}
+		result[i] = c
+	}
+	return result
+}
+
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,285 @@
+package go_rsql_parser
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestOptimize gathers all the tests for the Optimize simplification pass.
+func TestOptimize(t *testing.T) {
+	t.Run("TestOptimizeTightensRepeatedGt", testOptimizeTightensRepeatedGt)
+	t.Run("TestOptimizeMergesRange", testOptimizeMergesRange)
+	t.Run("TestOptimizeIntersectsIn", testOptimizeIntersectsIn)
+	t.Run("TestOptimizeEmptyResult", testOptimizeEmptyResult)
+	t.Run("TestOptimizeFoldsOrDuplicates", testOptimizeFoldsOrDuplicates)
+	t.Run("TestOptimizeAbsorbsOrSuperset", testOptimizeAbsorbsOrSuperset)
+	t.Run("TestOptimizeKeepsNonNumericEqualityBound", testOptimizeKeepsNonNumericEqualityBound)
+	t.Run("TestOptimizeEqualityMatchesNumericallyEqualSet", testOptimizeEqualityMatchesNumericallyEqualSet)
+	t.Run("TestOptimizeDetectsContradictoryBoundsWithNonNumericEquality", testOptimizeDetectsContradictoryBoundsWithNonNumericEquality)
+	t.Run("TestOptimizeMergedRangeCompilesToSameValueTypeAsUnmergedBound", testOptimizeMergedRangeCompilesToSameValueTypeAsUnmergedBound)
+	t.Run("TestOptimizeKeepsExactLiteralForLargeBounds", testOptimizeKeepsExactLiteralForLargeBounds)
+}
+
+// testOptimizeTightensRepeatedGt tests that two "gt" comparisons on the same field collapse to the tighter one.
+func testOptimizeTightensRepeatedGt(t *testing.T) {
+	ast, err := Parse("age==gt==10;age==gt==5")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimized, err := Optimize(ast)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	cmp, ok := optimized.(*ComparisonNode)
+	if !ok {
+		t.Fatalf("Expected a single ComparisonNode, got %T", optimized)
+	}
+	if cmp.Field != "age" || cmp.Op != "gt" || cmp.Values[0] != "10" {
+		t.Errorf("Expected age==gt==10, got %s==%s==%v", cmp.Field, cmp.Op, cmp.Values)
+	}
+}
+
+// testOptimizeMergesRange tests that complementary "gt"/"lt" comparisons on the same field merge into a RangeNode.
+func testOptimizeMergesRange(t *testing.T) {
+	ast, err := Parse("age==gt==10;age==lt==20")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimized, err := Optimize(ast)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	rng, ok := optimized.(*RangeNode)
+	if !ok {
+		t.Fatalf("Expected a RangeNode, got %T", optimized)
+	}
+	if rng.Field != "age" || rng.Min.Value != 10 || rng.Min.Inclusive || rng.Max.Value != 20 || rng.Max.Inclusive {
+		t.Errorf("Unexpected range: %+v / min=%+v max=%+v", rng, rng.Min, rng.Max)
+	}
+}
+
+// testOptimizeIntersectsIn tests that two "in" comparisons on the same field intersect their value lists.
+func testOptimizeIntersectsIn(t *testing.T) {
+	ast, err := Parse("x==in==(a,b);x==in==(b,c)")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimized, err := Optimize(ast)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	cmp, ok := optimized.(*ComparisonNode)
+	if !ok {
+		t.Fatalf("Expected a single ComparisonNode, got %T", optimized)
+	}
+	if cmp.Op != "in" || !compareSlice(cmp.Values, []string{"b"}) {
+		t.Errorf("Expected x==in==(b), got %s==%s==%v", cmp.Field, cmp.Op, cmp.Values)
+	}
+}
+
+// testOptimizeEmptyResult tests that contradictory comparisons are reported as ErrEmptyResult.
+func testOptimizeEmptyResult(t *testing.T) {
+	cases := []string{
+		"x==eq==1;x==eq==2",
+		"x==in==(a,b);x==in==(c,d)",
+		"x==gt==10;x==lt==5",
+	}
+	for _, query := range cases {
+		ast, err := Parse(query)
+		if err != nil {
+			t.Fatalf("Error parsing RSQL query %q: %s", query, err)
+		}
+		_, err = Optimize(ast)
+		if !errors.Is(err, ErrEmptyResult) {
+			t.Errorf("Query %q: expected ErrEmptyResult, got %v", query, err)
+		}
+	}
+}
+
+// testOptimizeFoldsOrDuplicates tests that Optimize folds duplicate Or operands into one.
+func testOptimizeFoldsOrDuplicates(t *testing.T) {
+	ast, err := Parse("name==John,name==John")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimized, err := Optimize(ast)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	cmp, ok := optimized.(*ComparisonNode)
+	if !ok {
+		t.Fatalf("Expected the duplicate Or operands to fold into a single ComparisonNode, got %T", optimized)
+	}
+	if cmp.Field != "name" || cmp.Values[0] != "John" {
+		t.Errorf("Unexpected merged node: %+v", cmp)
+	}
+}
+
+// testOptimizeAbsorbsOrSuperset tests that "A or (A and B)" absorbs to "A".
+func testOptimizeAbsorbsOrSuperset(t *testing.T) {
+	ast, err := Parse("name==John,(name==John;age==gt==30)")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimized, err := Optimize(ast)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	cmp, ok := optimized.(*ComparisonNode)
+	if !ok {
+		t.Fatalf("Expected the Or to absorb to a single ComparisonNode, got %T", optimized)
+	}
+	if cmp.Field != "name" || cmp.Op != "==" || cmp.Values[0] != "John" {
+		t.Errorf("Unexpected absorbed node: %+v", cmp)
+	}
+}
+
+// testOptimizeKeepsNonNumericEqualityBound tests that a gt/lt bound on a field is kept
+// (not silently dropped) when it's merged with a non-numeric equality on the same field.
+func testOptimizeKeepsNonNumericEqualityBound(t *testing.T) {
+	ast, err := Parse("age==eq==abc;age==gt==10")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimized, err := Optimize(ast)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	and, ok := optimized.(*AndNode)
+	if !ok {
+		t.Fatalf("Expected an AndNode combining the equality and the bound, got %T", optimized)
+	}
+	operands := flattenAnd2(and)
+	if len(operands) != 2 {
+		t.Fatalf("Expected 2 operands, got %d: %+v", len(operands), operands)
+	}
+	var sawEq, sawGt bool
+	for _, operand := range operands {
+		cmp, ok := operand.(*ComparisonNode)
+		if !ok {
+			t.Fatalf("Expected a ComparisonNode operand, got %T", operand)
+		}
+		switch cmp.Op {
+		case "==":
+			sawEq = cmp.Field == "age" && cmp.Values[0] == "abc"
+		case "gt":
+			sawGt = cmp.Field == "age" && cmp.Values[0] == "10"
+		}
+	}
+	if !sawEq || !sawGt {
+		t.Errorf("Expected both age==abc and age==gt==10 to survive, got %+v", operands)
+	}
+}
+
+// testOptimizeEqualityMatchesNumericallyEqualSet tests that an equality value matches
+// an "in" set entry that's numerically (not textually) equal, e.g. "10.0" against "10".
+func testOptimizeEqualityMatchesNumericallyEqualSet(t *testing.T) {
+	ast, err := Parse("x==in==(10);x==eq==10.0")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimized, err := Optimize(ast)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	cmp, ok := optimized.(*ComparisonNode)
+	if !ok {
+		t.Fatalf("Expected a single ComparisonNode, got %T", optimized)
+	}
+	if cmp.Field != "x" || cmp.Op != "==" || cmp.Values[0] != "10.0" {
+		t.Errorf("Expected x==10.0, got %s==%s==%v", cmp.Field, cmp.Op, cmp.Values)
+	}
+}
+
+// testOptimizeDetectsContradictoryBoundsWithNonNumericEquality tests that a self-contradictory
+// gt/lt bound pair is still reported as ErrEmptyResult even alongside a non-numeric equality.
+func testOptimizeDetectsContradictoryBoundsWithNonNumericEquality(t *testing.T) {
+	ast, err := Parse("age==eq==abc;age==gt==50;age==lt==10")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	_, err = Optimize(ast)
+	if !errors.Is(err, ErrEmptyResult) {
+		t.Errorf("Expected ErrEmptyResult, got %v", err)
+	}
+}
+
+// testOptimizeMergedRangeCompilesToSameValueTypeAsUnmergedBound tests that
+// a RangeNode produced by merging two complementary bounds compiles to the
+// same value type (string) as a single, unmerged gt/lt ComparisonNode,
+// so a filter's value type doesn't depend on whether two bounds happened
+// to combine.
+func testOptimizeMergedRangeCompilesToSameValueTypeAsUnmergedBound(t *testing.T) {
+	merged, err := Parse("age==gt==10;age==lt==20")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimizedMerged, err := Optimize(merged)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	mergedMongo, err := ConvertToMongo(optimizedMerged)
+	if err != nil {
+		t.Fatalf("Error converting merged range to Mongo: %s", err)
+	}
+
+	unmerged, err := Parse("age==gt==10")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	unmergedMongo, err := ConvertToMongo(unmerged)
+	if err != nil {
+		t.Fatalf("Error converting unmerged bound to Mongo: %s", err)
+	}
+
+	mergedGt := mergedMongo["age"].(bson.M)["$gt"]
+	unmergedGt := unmergedMongo["age"].(bson.M)["$gt"]
+	if _, ok := mergedGt.(string); !ok {
+		t.Errorf("Expected merged range's $gt to be a string like the unmerged bound, got %T (%v)", mergedGt, mergedGt)
+	}
+	if mergedGt != unmergedGt {
+		t.Errorf("Expected merged range's $gt (%v, %T) to equal the unmerged bound's $gt (%v, %T)", mergedGt, mergedGt, unmergedGt, unmergedGt)
+	}
+}
+
+// testOptimizeKeepsExactLiteralForLargeBounds tests that merging bounds
+// with large magnitudes keeps the exact original literal rather than a
+// float64 round-trip, which would switch to scientific notation (e.g.
+// "2e+06") for values at or above 1,000,000 and break any downstream
+// parser (a Schema's strconv.Atoi included) expecting a plain integer.
+func testOptimizeKeepsExactLiteralForLargeBounds(t *testing.T) {
+	ast, err := Parse("id==gt==2000000;id==lt==3000000")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	optimized, err := Optimize(ast)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	rng, ok := optimized.(*RangeNode)
+	if !ok {
+		t.Fatalf("Expected a RangeNode, got %T", optimized)
+	}
+	if rng.Min.Raw != "2000000" {
+		t.Errorf("Expected the Min bound's Raw literal to stay \"2000000\", got %q", rng.Min.Raw)
+	}
+	if rng.Max.Raw != "3000000" {
+		t.Errorf("Expected the Max bound's Raw literal to stay \"3000000\", got %q", rng.Max.Raw)
+	}
+
+	s := NewSchema()
+	s.RegisterField("id", "id", Int)
+	result, err := s.apply(optimized)
+	if err != nil {
+		t.Fatalf("Error applying schema to a large-valued range: %s", err)
+	}
+	coercedRange := result.(*RangeNode)
+	if coercedRange.Min.Coerced != 2000000 {
+		t.Errorf("Expected the Min bound to coerce to int 2000000, got %v (%T)", coercedRange.Min.Coerced, coercedRange.Min.Coerced)
+	}
+	if coercedRange.Max.Coerced != 3000000 {
+		t.Errorf("Expected the Max bound to coerce to int 3000000, got %v (%T)", coercedRange.Max.Coerced, coercedRange.Max.Coerced)
+	}
+}
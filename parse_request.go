@@ -0,0 +1,152 @@
+package go_rsql_parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	mongooptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ParseResult is the structured outcome of parsing a request's RSQL
+// query: the compiled MongoDB filter plus any paging/ordering carried
+// by the query's reserved "sort"/"limit"/"offset" fields.
+type ParseResult struct {
+	Filter bson.M
+	Sort   bson.D
+	Limit  *int64
+	Skip   *int64
+}
+
+// MongoFindOptions builds the *options.FindOptions equivalent of r's
+// Sort/Limit/Skip, ready to pass alongside r.Filter to a Collection's
+// Find method.
+func (r *ParseResult) MongoFindOptions() *mongooptions.FindOptions {
+	opts := mongooptions.Find()
+	if len(r.Sort) > 0 {
+		opts.SetSort(r.Sort)
+	}
+	if r.Limit != nil {
+		opts.SetLimit(*r.Limit)
+	}
+	if r.Skip != nil {
+		opts.SetSkip(*r.Skip)
+	}
+	return opts
+}
+
+// ParseRequest parses query into a ParseResult. In addition to ordinary
+// comparisons, query may carry the reserved fields "sort" (a
+// quoted, comma-separated list of field names, "-" prefixed for
+// descending order), "limit" and "offset", e.g.:
+//
+//	age==gt==30;sort=='name,-age';limit==50;offset==100
+//
+// Reserved fields are stripped from the compiled Filter and returned
+// via Sort/Limit/Skip instead. ParseRequest only supports the MongoDB
+// dialect, since Sort/Limit/Skip are expressed in MongoDB driver terms.
+func ParseRequest(query string, dbType string, opts ...Option) (*ParseResult, error) {
+	if dbType != MongoDB {
+		return nil, errors.New("unsupported database type")
+	}
+
+	ast, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	filterAst, sort, limit, skip, err := extractControls(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.schema != nil && filterAst != nil {
+		filterAst, err = o.schema.apply(filterAst)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filter := bson.M{}
+	if filterAst != nil {
+		filter, err = ConvertToMongo(filterAst)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ParseResult{Filter: filter, Sort: sort, Limit: limit, Skip: skip}, nil
+}
+
+// extractControls splits query's top-level conjuncts into the
+// remaining filter (nil if every conjunct was a reserved field) and the
+// "sort"/"limit"/"offset" reserved fields it carried.
+func extractControls(n Node) (filter Node, sort bson.D, limit *int64, skip *int64, err error) {
+	var kept []Node
+	for _, operand := range flattenAnd2(n) {
+		cmp, ok := operand.(*ComparisonNode)
+		if !ok {
+			kept = append(kept, operand)
+			continue
+		}
+		switch cmp.Field {
+		case "sort":
+			if sort, err = parseSort(stringValue(cmp)); err != nil {
+				return nil, nil, nil, nil, err
+			}
+		case "limit":
+			if limit, err = parseIntField("limit", stringValue(cmp)); err != nil {
+				return nil, nil, nil, nil, err
+			}
+		case "offset":
+			if skip, err = parseIntField("offset", stringValue(cmp)); err != nil {
+				return nil, nil, nil, nil, err
+			}
+		default:
+			kept = append(kept, operand)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, sort, limit, skip, nil
+	}
+	return buildAnd(kept), sort, limit, skip, nil
+}
+
+// parseSort parses a "field1,-field2" sort specification into a bson.D,
+// where a "-" prefix means descending order.
+func parseSort(raw string) (bson.D, error) {
+	fields := strings.Split(raw, ",")
+	sort := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		direction := 1
+		if strings.HasPrefix(field, "-") {
+			direction = -1
+			field = field[1:]
+		}
+		if field == "" {
+			return nil, errors.New("rsql: empty sort field")
+		}
+		sort = append(sort, bson.E{Key: field, Value: direction})
+	}
+	return sort, nil
+}
+
+// parseIntField parses the raw value of a "limit"/"offset" reserved
+// field into an *int64.
+func parseIntField(name, raw string) (*int64, error) {
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("rsql: %s must be an integer, got %q", name, raw)
+	}
+	return &v, nil
+}
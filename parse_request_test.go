@@ -0,0 +1,102 @@
+package go_rsql_parser
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestParseRequest gathers all the tests for ParseRequest and ParseResult.
+func TestParseRequest(t *testing.T) {
+	t.Run("TestParseRequestFilterSortLimitOffset", testParseRequestFilterSortLimitOffset)
+	t.Run("TestParseRequestFindOptionsRoundTrip", testParseRequestFindOptionsRoundTrip)
+	t.Run("TestParseRequestControlsOnly", testParseRequestControlsOnly)
+	t.Run("TestParseRequestUnsupportedDBType", testParseRequestUnsupportedDBType)
+	t.Run("TestParseRSQLStillReturnsOnlyFilter", testParseRSQLStillReturnsOnlyFilter)
+}
+
+// testParseRequestFilterSortLimitOffset tests parsing a query carrying a filter plus all three reserved fields.
+func testParseRequestFilterSortLimitOffset(t *testing.T) {
+	query := "age==gt==30;sort=='name,-age';limit==50;offset==100"
+	result, err := ParseRequest(query, MongoDB)
+	if err != nil {
+		t.Fatalf("Error parsing RSQL request: %s", err)
+	}
+
+	expectedFilter := bson.M{"age": bson.M{"$gt": "30"}}
+	if !compareMaps(result.Filter, expectedFilter) {
+		t.Errorf("Parsed filter does not match expected filter: %+v", result.Filter)
+	}
+
+	expectedSort := bson.D{{Key: "name", Value: 1}, {Key: "age", Value: -1}}
+	if len(result.Sort) != len(expectedSort) {
+		t.Fatalf("Expected sort %+v, got %+v", expectedSort, result.Sort)
+	}
+	for i, e := range expectedSort {
+		if result.Sort[i].Key != e.Key || result.Sort[i].Value != e.Value {
+			t.Errorf("Expected sort entry %+v at index %d, got %+v", e, i, result.Sort[i])
+		}
+	}
+
+	if result.Limit == nil || *result.Limit != 50 {
+		t.Errorf("Expected limit 50, got %v", result.Limit)
+	}
+	if result.Skip == nil || *result.Skip != 100 {
+		t.Errorf("Expected offset 100, got %v", result.Skip)
+	}
+}
+
+// testParseRequestFindOptionsRoundTrip tests that MongoFindOptions reflects the parsed sort/limit/offset.
+func testParseRequestFindOptionsRoundTrip(t *testing.T) {
+	query := "sort=='-age';limit==10;offset==5"
+	result, err := ParseRequest(query, MongoDB)
+	if err != nil {
+		t.Fatalf("Error parsing RSQL request: %s", err)
+	}
+	findOptions := result.MongoFindOptions()
+	if findOptions.Limit == nil || *findOptions.Limit != 10 {
+		t.Errorf("Expected FindOptions.Limit 10, got %v", findOptions.Limit)
+	}
+	if findOptions.Skip == nil || *findOptions.Skip != 5 {
+		t.Errorf("Expected FindOptions.Skip 5, got %v", findOptions.Skip)
+	}
+	if findOptions.Sort == nil {
+		t.Errorf("Expected FindOptions.Sort to be set")
+	}
+}
+
+// testParseRequestControlsOnly tests a query consisting only of reserved fields, with no filter.
+func testParseRequestControlsOnly(t *testing.T) {
+	query := "limit==20"
+	result, err := ParseRequest(query, MongoDB)
+	if err != nil {
+		t.Fatalf("Error parsing RSQL request: %s", err)
+	}
+	if len(result.Filter) != 0 {
+		t.Errorf("Expected an empty filter, got %+v", result.Filter)
+	}
+	if result.Limit == nil || *result.Limit != 20 {
+		t.Errorf("Expected limit 20, got %v", result.Limit)
+	}
+}
+
+// testParseRequestUnsupportedDBType tests that ParseRequest rejects non-MongoDB dialects.
+func testParseRequestUnsupportedDBType(t *testing.T) {
+	_, err := ParseRequest("age==30", SQL)
+	if err == nil {
+		t.Errorf("Expected an error for an unsupported database type")
+	}
+}
+
+// testParseRSQLStillReturnsOnlyFilter tests that ParseRSQL remains a Filter-only, backward-compatible wrapper.
+func testParseRSQLStillReturnsOnlyFilter(t *testing.T) {
+	query := "age==gt==30"
+	filter, err := ParseRSQL(query, MongoDB)
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	expected := bson.M{"age": bson.M{"$gt": "30"}}
+	if !compareMaps(filter, expected) {
+		t.Errorf("Parsed query does not match expected query")
+	}
+}
@@ -0,0 +1,105 @@
+package go_rsql_parser
+
+import "errors"
+
+// parser turns a token stream produced by the lexer into an RSQL AST.
+type parser struct {
+	lex     *lexer
+	current token
+}
+
+// Parse parses an RSQL query string into an AST.
+//
+// Grammar:
+//
+//	expr     := andExpr ( ("," | "or") andExpr )*
+//	andExpr  := primary ( (";" | "and") primary )*
+//	primary  := "(" expr ")" | comparison
+func Parse(query string) (Node, error) {
+	p := &parser{lex: newLexer(query)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.current.kind != tokEOF {
+		return nil, errors.New("rsql: unexpected trailing input")
+	}
+	return node, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.current = tok
+	return nil
+}
+
+func (p *parser) parseExpr() (Node, error) {
+	left, err := p.parseAndExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.current.kind == tokOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAndExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = &OrNode{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAndExpr() (Node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.current.kind == tokAnd {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &AndNode{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	switch p.current.kind {
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.current.kind != tokRParen {
+			return nil, errors.New("rsql: expected closing ')'")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case tokComparison:
+		node := p.current.comp
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	default:
+		return nil, errors.New("rsql: unexpected token in query")
+	}
+}
@@ -2,101 +2,72 @@ package go_rsql_parser
 
 import (
 	"errors"
+
 	"go.mongodb.org/mongo-driver/bson"
-	"strings"
 )
 
 const (
-	MongoDB = "mongo"
+	MongoDB       = "mongo"
+	SQL           = "sql"
+	Elasticsearch = "elasticsearch"
 )
 
-// validOperators is a list of valid RSQL operators.
-var validOperators = [11]string{"==", "eq", "ne", "gt", "ge", "lt", "le", "in", "out", "like", "ilike"}
+// Option configures a ParseRSQL/Compile call.
+type Option func(*options)
 
-type QueryPart struct {
-	Field    string
-	Operator string
-	Value    string
+type options struct {
+	schema *Schema
 }
 
-// ParseRSQL parses the given RSQL query string and returns the parsed query.
-func ParseRSQL(query string, dbType string) (bson.M, error) {
-	if dbType == MongoDB {
-		// Split the query into parts
-		parts := splitQuery(query)
-		// Convert the parts to a MongoDB query
-		mongoQuery, err := convertToMongoQuery(parts)
-		if err != nil {
-			return nil, err
-		}
-		return mongoQuery, nil
-	} else {
-		return nil, errors.New("unsupported database type")
-	}
+// WithSchema validates and rewrites the parsed query against s: unknown
+// fields are rejected, api-facing field names are translated to their
+// db names, literals are coerced to their declared Go type, and
+// disallowed operators or oversized "in"/"out" lists are rejected.
+func WithSchema(s *Schema) Option {
+	return func(o *options) { o.schema = s }
 }
 
-// splitQuery splits the given RSQL query string into parts.
-func splitQuery(fullQuery string) []QueryPart {
-	// Split the query into parts
-	queryParts := strings.Split(fullQuery, ";")
-	// Each part in the query is a field, operator, and value separated by "==", and can be in the form of "field==value" or "field=={operator}=value"
-	parts := make([]QueryPart, 0)
-	for _, part := range queryParts {
-		// Split the part into field, operator, and value
-		partParts := strings.Split(part, "==")
-		if len(partParts) == 2 {
-			parts = append(parts, QueryPart{Field: partParts[0], Operator: "==", Value: partParts[1]})
-		} else if len(partParts) == 3 {
-			parts = append(parts, QueryPart{Field: partParts[0], Operator: partParts[1], Value: partParts[2]})
-		}
+// ParseRSQL parses the given RSQL query string into a MongoDB filter. It
+// is a convenience wrapper over Compile for callers that only need
+// MongoDB support; use Compile directly to target other dialects.
+func ParseRSQL(query string, dbType string, opts ...Option) (bson.M, error) {
+	native, _, err := Compile(query, dbType, opts...)
+	if err != nil {
+		return nil, err
+	}
+	mongoQuery, ok := native.(bson.M)
+	if !ok {
+		return nil, errors.New("rsql: dialect '" + dbType + "' does not produce a MongoDB query")
 	}
-	return parts
+	return mongoQuery, nil
 }
 
-// convertToMongoQuery converts the given RSQL query parts to a MongoDB query.
-func convertToMongoQuery(parts []QueryPart) (bson.M, error) {
-	var query bson.M = bson.M{}
-	for _, part := range parts {
-		if !isValidOperator(part.Operator) {
-			return nil, errors.New("invalid operator: " + part.Operator)
-		} else {
-			// Convert the part to a MongoDB query
-			if part.Operator == "eq" || part.Operator == "==" {
-				query[part.Field] = bson.M{"$eq": part.Value}
-			} else if part.Operator == "ne" {
-				query[part.Field] = bson.M{"$ne": part.Value}
-			} else if part.Operator == "gt" {
-				query[part.Field] = bson.M{"$gt": part.Value}
-			} else if part.Operator == "ge" {
-				query[part.Field] = bson.M{"$gte": part.Value}
-			} else if part.Operator == "lt" {
-				query[part.Field] = bson.M{"$lt": part.Value}
-			} else if part.Operator == "le" {
-				query[part.Field] = bson.M{"$lte": part.Value}
-			} else if part.Operator == "in" {
-				// Value will be in the form "(value1,value2,value3)", so we ignore the first and last parentheses and split the values by ","
-				values := strings.Split(part.Value[1:len(part.Value)-1], ",")
-				query[part.Field] = bson.M{"$in": values}
-			} else if part.Operator == "out" {
-				// Value will be in the form "(value1,value2,value3)", so we ignore the first and last parentheses and split the values by ","
-				values := strings.Split(part.Value[1:len(part.Value)-1], ",")
-				query[part.Field] = bson.M{"$nin": values}
-			} else if part.Operator == "like" {
-				query[part.Field] = bson.M{"$regex": part.Value}
-			} else if part.Operator == "ilike" {
-				query[part.Field] = bson.M{"$regex": "(?i)" + part.Value}
-			}
-		}
+// Compile parses query into an AST and compiles it with the Dialect
+// registered under dbType, returning the backend-native query and any
+// positional arguments the dialect requires. Dialects are registered
+// via RegisterDialect.
+func Compile(query string, dbType string, opts ...Option) (any, []any, error) {
+	dialect, ok := dialects[dbType]
+	if !ok {
+		return nil, nil, errors.New("unsupported database type")
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
 	}
-	return query, nil
-}
 
-// isValidOperator checks if the given operator is a valid RSQL operator.
-func isValidOperator(operator string) bool {
-	for _, validOperator := range validOperators {
-		if operator == validOperator {
-			return true
+	ast, err := Parse(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if o.schema != nil {
+		ast, err = o.schema.apply(ast)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
-	return false
+
+	return dialect.Compile(ast)
 }
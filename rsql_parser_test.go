@@ -30,6 +30,17 @@ func compareMaps(a, b bson.M) bool {
 			if !compareMaps(value.(bson.M), b[key].(bson.M)) {
 				return false
 			}
+		} else if clauses, ok := value.([]bson.M); ok {
+			// value is a slice of maps (e.g. "$and"/"$or"), so compare element-wise.
+			bsonClauses, ok := b[key].([]bson.M)
+			if !ok || len(clauses) != len(bsonClauses) {
+				return false
+			}
+			for i, clause := range clauses {
+				if !compareMaps(clause, bsonClauses[i]) {
+					return false
+				}
+			}
 		} else {
 			// If both values are strings, compare them. Otherwise, convert them to strings and compare.
 			bsonValue := b[key]
@@ -83,6 +94,28 @@ func TestRSQLParser(t *testing.T) {
 	t.Run("TestParseRSQLSingleNotIn", testParseRSQLSingleNotIn)
 	t.Run("TestParseRSQLSingleLike", testParseRSQLSingleLike)
 	t.Run("TestParseRSQLSingleILike", testParseRSQLSingleILike)
+	t.Run("TestParseRSQLOr", testParseRSQLOr)
+	t.Run("TestParseRSQLGroup", testParseRSQLGroup)
+	t.Run("TestParseRSQLQuotedValue", testParseRSQLQuotedValue)
+	t.Run("TestParseRSQLValueListRejectedForNonInOutOp", testParseRSQLValueListRejectedForNonInOutOp)
+}
+
+// testParseRSQLValueListRejectedForNonInOutOp tests that a "(a,b,c)" value
+// list is rejected as malformed for operators other than "in"/"out",
+// instead of silently keeping only the first element.
+func testParseRSQLValueListRejectedForNonInOutOp(t *testing.T) {
+	query := "age==gt==(1,2)"
+	dbType := MongoDB
+	_, err := ParseRSQL(query, dbType)
+	if err == nil {
+		t.Errorf("Expected an error for a value list used with 'gt'")
+	}
+
+	query = "name==(a,b)"
+	_, err = ParseRSQL(query, dbType)
+	if err == nil {
+		t.Errorf("Expected an error for a value list used with the default '==' operator")
+	}
 }
 
 // testParseRSQLUnsupportedDBType tests parsing an RSQL query with an unsupported database type.
@@ -121,14 +154,69 @@ func testParseRSQLMultiple(t *testing.T) {
 		t.Errorf("Error parsing RSQL query: %s", err)
 	}
 	expectedQuery := bson.M{
-		"name": bson.M{
-			"$eq": "John",
+		"$and": []bson.M{
+			{"name": bson.M{"$eq": "John"}},
+			{"age": bson.M{"$gt": 30}},
+			{"city": bson.M{"$regex": "New York"}},
 		},
-		"age": bson.M{
-			"$gt": 30,
+	}
+	if !compareMaps(parsedQuery, expectedQuery) {
+		t.Errorf("Parsed query does not match expected query")
+	}
+}
+
+// testParseRSQLOr tests parsing an RSQL query joined with the "," OR operator.
+func testParseRSQLOr(t *testing.T) {
+	query := "name==John,name==Jane"
+	dbType := MongoDB
+	parsedQuery, err := ParseRSQL(query, dbType)
+	if err != nil {
+		t.Errorf("Error parsing RSQL query: %s", err)
+	}
+	expectedQuery := bson.M{
+		"$or": []bson.M{
+			{"name": bson.M{"$eq": "John"}},
+			{"name": bson.M{"$eq": "Jane"}},
+		},
+	}
+	if !compareMaps(parsedQuery, expectedQuery) {
+		t.Errorf("Parsed query does not match expected query")
+	}
+}
+
+// testParseRSQLGroup tests parsing a parenthesized group combined with an AND clause.
+func testParseRSQLGroup(t *testing.T) {
+	query := "(name==John,name==Jane);age==gt==30"
+	dbType := MongoDB
+	parsedQuery, err := ParseRSQL(query, dbType)
+	if err != nil {
+		t.Errorf("Error parsing RSQL query: %s", err)
+	}
+	expectedQuery := bson.M{
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"name": bson.M{"$eq": "John"}},
+				{"name": bson.M{"$eq": "Jane"}},
+			}},
+			{"age": bson.M{"$gt": 30}},
 		},
-		"city": bson.M{
-			"$regex": "New York",
+	}
+	if !compareMaps(parsedQuery, expectedQuery) {
+		t.Errorf("Parsed query does not match expected query")
+	}
+}
+
+// testParseRSQLQuotedValue tests parsing a quoted value containing a separator character.
+func testParseRSQLQuotedValue(t *testing.T) {
+	query := `name=="Doe, John"`
+	dbType := MongoDB
+	parsedQuery, err := ParseRSQL(query, dbType)
+	if err != nil {
+		t.Errorf("Error parsing RSQL query: %s", err)
+	}
+	expectedQuery := bson.M{
+		"name": bson.M{
+			"$eq": "Doe, John",
 		},
 	}
 	if !compareMaps(parsedQuery, expectedQuery) {
@@ -0,0 +1,273 @@
+package go_rsql_parser
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldKind identifies the Go/BSON type an RSQL field's values are
+// coerced to once a Schema is in play.
+type FieldKind int
+
+const (
+	String FieldKind = iota
+	Int
+	Float
+	Bool
+	Time
+	ObjectID
+	Enum
+)
+
+// ErrUnknownField is returned by Schema when a query references a field
+// that was never registered.
+var ErrUnknownField = fmt.Errorf("rsql: unknown field")
+
+// ErrOperatorNotAllowed is returned by Schema when a query uses an
+// operator that isn't permitted for the referenced field.
+var ErrOperatorNotAllowed = fmt.Errorf("rsql: operator not allowed for field")
+
+// fieldDef is a single registered Schema field.
+type fieldDef struct {
+	apiName    string
+	dbName     string
+	kind       FieldKind
+	allowedOps []string
+	enumValues []string
+	maxIn      int
+}
+
+// FieldOption configures a field registered via Schema.RegisterField.
+type FieldOption func(*fieldDef)
+
+// AllowedOps restricts a field to the given set of RSQL operators,
+// overriding the kind's default set.
+func AllowedOps(ops ...string) FieldOption {
+	return func(f *fieldDef) { f.allowedOps = ops }
+}
+
+// EnumValues restricts an Enum field's values to the given set.
+func EnumValues(values ...string) FieldOption {
+	return func(f *fieldDef) { f.enumValues = values }
+}
+
+// MaxIn caps the number of values an "in"/"out" comparison may carry for
+// the field.
+func MaxIn(max int) FieldOption {
+	return func(f *fieldDef) { f.maxIn = max }
+}
+
+// Schema is a whitelist of the RSQL fields a query may reference,
+// mapping each API-facing field name to its backing field name, Go
+// type and permitted operators. Parsing a query with a Schema (via
+// WithSchema) rejects unknown fields, translates field names, coerces
+// literals to their declared type, and enforces per-field operator and
+// "in"/"out" list-size limits.
+type Schema struct {
+	fields map[string]*fieldDef
+}
+
+// NewSchema creates an empty Schema.
+func NewSchema() *Schema {
+	return &Schema{fields: make(map[string]*fieldDef)}
+}
+
+// RegisterField adds a field to the schema. apiName is the name clients
+// use in RSQL queries; dbName is the name it's translated to in the
+// compiled query.
+func (s *Schema) RegisterField(apiName, dbName string, kind FieldKind, opts ...FieldOption) {
+	f := &fieldDef{apiName: apiName, dbName: dbName, kind: kind}
+	for _, opt := range opts {
+		opt(f)
+	}
+	s.fields[apiName] = f
+}
+
+// apply walks an RSQL AST, validating and rewriting every ComparisonNode
+// against the schema.
+func (s *Schema) apply(n Node) (Node, error) {
+	switch v := n.(type) {
+	case *AndNode:
+		left, err := s.apply(v.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := s.apply(v.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &AndNode{Left: left, Right: right}, nil
+	case *OrNode:
+		left, err := s.apply(v.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := s.apply(v.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &OrNode{Left: left, Right: right}, nil
+	case *ComparisonNode:
+		return s.applyComparison(v)
+	case *RangeNode:
+		return s.applyRange(v)
+	case *MatchNone:
+		// MatchNone carries no field, so there's nothing for a Schema to
+		// validate or coerce; it's only reachable here when Optimize ran
+		// before the Schema.
+		return v, nil
+	default:
+		return nil, fmt.Errorf("rsql: unsupported AST node")
+	}
+}
+
+// applyRange validates and translates a RangeNode the same way
+// applyComparison does for a lone ComparisonNode. A RangeNode only
+// reaches here when Optimize merged a field's bounds before the Schema
+// ran, so its Field is still the api name and its bounds are unvalidated
+// against the field's allowed operators.
+func (s *Schema) applyRange(r *RangeNode) (*RangeNode, error) {
+	def, ok := s.fields[r.Field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownField, r.Field)
+	}
+
+	allowedOps := def.allowedOps
+	if len(allowedOps) == 0 {
+		allowedOps = defaultAllowedOps(def.kind)
+	}
+
+	min, err := coerceBound(def, allowedOps, r.Min, "gt", "ge")
+	if err != nil {
+		return nil, err
+	}
+	max, err := coerceBound(def, allowedOps, r.Max, "lt", "le")
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeNode{Field: def.dbName, Min: min, Max: max}, nil
+}
+
+// coerceBound validates b's operator against allowedOps and coerces its
+// value to def's declared type, returning a new Bound carrying the
+// coercion (or nil if b is nil). Without this, a RangeNode Schema'd
+// after Optimize would keep its raw-string bound values instead of the
+// Int/Float/etc. a lone ComparisonNode gets from applyComparison.
+func coerceBound(def *fieldDef, allowedOps []string, b *Bound, exclusiveOp, inclusiveOp string) (*Bound, error) {
+	if b == nil {
+		return nil, nil
+	}
+	op := boundOp(b, exclusiveOp, inclusiveOp)
+	if !containsString(allowedOps, op) {
+		return nil, fmt.Errorf("%w: %q on field %q", ErrOperatorNotAllowed, op, def.apiName)
+	}
+	coerced, err := coerceValue(def, b.Raw)
+	if err != nil {
+		return nil, err
+	}
+	return &Bound{Value: b.Value, Inclusive: b.Inclusive, Raw: b.Raw, Coerced: coerced}, nil
+}
+
+func (s *Schema) applyComparison(c *ComparisonNode) (*ComparisonNode, error) {
+	def, ok := s.fields[c.Field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownField, c.Field)
+	}
+
+	allowedOps := def.allowedOps
+	if len(allowedOps) == 0 {
+		allowedOps = defaultAllowedOps(def.kind)
+	}
+	if !containsString(allowedOps, c.Op) {
+		return nil, fmt.Errorf("%w: %q on field %q", ErrOperatorNotAllowed, c.Op, c.Field)
+	}
+
+	if (c.Op == "in" || c.Op == "out") && def.maxIn > 0 && len(c.Values) > def.maxIn {
+		return nil, fmt.Errorf("rsql: field %q allows at most %d values in %q, got %d", c.Field, def.maxIn, c.Op, len(c.Values))
+	}
+
+	coerced := make([]any, len(c.Values))
+	for i, raw := range c.Values {
+		v, err := coerceValue(def, raw)
+		if err != nil {
+			return nil, err
+		}
+		coerced[i] = v
+	}
+
+	return &ComparisonNode{Field: def.dbName, Op: c.Op, Values: c.Values, Coerced: coerced}, nil
+}
+
+// defaultAllowedOps returns the operators permitted for a field kind
+// when a field doesn't declare an explicit AllowedOps option.
+func defaultAllowedOps(kind FieldKind) []string {
+	switch kind {
+	case String:
+		return []string{"==", "eq", "ne", "in", "out", "like", "ilike"}
+	case Bool:
+		return []string{"==", "eq", "ne"}
+	case Enum, ObjectID:
+		return []string{"==", "eq", "ne", "in", "out"}
+	default: // Int, Float, Time
+		return []string{"==", "eq", "ne", "gt", "ge", "lt", "le", "in", "out"}
+	}
+}
+
+// coerceValue converts a raw RSQL literal to the Go type declared for
+// def's field kind.
+func coerceValue(def *fieldDef, raw string) (any, error) {
+	switch def.kind {
+	case String:
+		return raw, nil
+	case Int:
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("rsql: field %q expects an int, got %q", def.apiName, raw)
+		}
+		return v, nil
+	case Float:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("rsql: field %q expects a float, got %q", def.apiName, raw)
+		}
+		return v, nil
+	case Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("rsql: field %q expects a bool, got %q", def.apiName, raw)
+		}
+		return v, nil
+	case Time:
+		v, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("rsql: field %q expects an RFC3339 time, got %q", def.apiName, raw)
+		}
+		return v, nil
+	case ObjectID:
+		v, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			return nil, fmt.Errorf("rsql: field %q expects an ObjectID, got %q", def.apiName, raw)
+		}
+		return v, nil
+	case Enum:
+		if len(def.enumValues) > 0 && !containsString(def.enumValues, raw) {
+			return nil, fmt.Errorf("rsql: value %q not allowed for enum field %q", raw, def.apiName)
+		}
+		return raw, nil
+	default:
+		return raw, nil
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package go_rsql_parser
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestSchema gathers all the tests for Schema-based field validation and
+// value coercion.
+func TestSchema(t *testing.T) {
+	t.Run("TestSchemaCoercesTypes", testSchemaCoercesTypes)
+	t.Run("TestSchemaTranslatesFieldName", testSchemaTranslatesFieldName)
+	t.Run("TestSchemaRejectsUnknownField", testSchemaRejectsUnknownField)
+	t.Run("TestSchemaRejectsDisallowedOperator", testSchemaRejectsDisallowedOperator)
+	t.Run("TestSchemaEnforcesMaxIn", testSchemaEnforcesMaxIn)
+	t.Run("TestSchemaRejectsInvalidEnumValue", testSchemaRejectsInvalidEnumValue)
+	t.Run("TestSchemaCoercesObjectID", testSchemaCoercesObjectID)
+	t.Run("TestSchemaThenOptimizePreservesCoercion", testSchemaThenOptimizePreservesCoercion)
+	t.Run("TestOptimizeThenSchemaValidatesRangeNode", testOptimizeThenSchemaValidatesRangeNode)
+}
+
+func testSchema() *Schema {
+	s := NewSchema()
+	s.RegisterField("age", "age", Int)
+	s.RegisterField("name", "full_name", String)
+	s.RegisterField("status", "status", Enum, EnumValues("active", "inactive"))
+	s.RegisterField("id", "_id", ObjectID)
+	s.RegisterField("tags", "tags", String, MaxIn(2))
+	return s
+}
+
+// testSchemaCoercesTypes tests that a Schema coerces an "gt" int comparison to a Go int.
+func testSchemaCoercesTypes(t *testing.T) {
+	query := "age==gt==30"
+	parsedQuery, err := ParseRSQL(query, MongoDB, WithSchema(testSchema()))
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	value := parsedQuery["age"].(bson.M)["$gt"]
+	if _, ok := value.(int); !ok {
+		t.Errorf("Expected a coerced int value, got %T (%v)", value, value)
+	}
+}
+
+// testSchemaTranslatesFieldName tests that a Schema rewrites the api field name to its db name.
+func testSchemaTranslatesFieldName(t *testing.T) {
+	query := "name==John"
+	parsedQuery, err := ParseRSQL(query, MongoDB, WithSchema(testSchema()))
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	if _, ok := parsedQuery["full_name"]; !ok {
+		t.Errorf("Expected the query to be translated to the db field name 'full_name', got %v", parsedQuery)
+	}
+}
+
+// testSchemaRejectsUnknownField tests that a Schema rejects a field it has no definition for.
+func testSchemaRejectsUnknownField(t *testing.T) {
+	query := "secret==1"
+	_, err := ParseRSQL(query, MongoDB, WithSchema(testSchema()))
+	if !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Expected ErrUnknownField, got %v", err)
+	}
+}
+
+// testSchemaRejectsDisallowedOperator tests that a Schema rejects "like" on an Int field.
+func testSchemaRejectsDisallowedOperator(t *testing.T) {
+	query := "age==like==30"
+	_, err := ParseRSQL(query, MongoDB, WithSchema(testSchema()))
+	if !errors.Is(err, ErrOperatorNotAllowed) {
+		t.Errorf("Expected ErrOperatorNotAllowed, got %v", err)
+	}
+}
+
+// testSchemaEnforcesMaxIn tests that a Schema rejects an "in" list longer than MaxIn.
+func testSchemaEnforcesMaxIn(t *testing.T) {
+	query := "tags==in==(a,b,c)"
+	_, err := ParseRSQL(query, MongoDB, WithSchema(testSchema()))
+	if err == nil {
+		t.Errorf("Expected an error for exceeding MaxIn")
+	}
+}
+
+// testSchemaRejectsInvalidEnumValue tests that a Schema rejects a value outside an Enum field's allowed set.
+func testSchemaRejectsInvalidEnumValue(t *testing.T) {
+	query := "status==pending"
+	_, err := ParseRSQL(query, MongoDB, WithSchema(testSchema()))
+	if err == nil {
+		t.Errorf("Expected an error for an invalid enum value")
+	}
+}
+
+// testSchemaCoercesObjectID tests that a Schema coerces a hex string to a primitive.ObjectID.
+func testSchemaCoercesObjectID(t *testing.T) {
+	hex := "507f1f77bcf86cd799439011"
+	query := "id==" + hex
+	parsedQuery, err := ParseRSQL(query, MongoDB, WithSchema(testSchema()))
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	value := parsedQuery["_id"].(bson.M)["$eq"]
+	oid, ok := value.(primitive.ObjectID)
+	if !ok {
+		t.Fatalf("Expected a coerced primitive.ObjectID, got %T", value)
+	}
+	expected, _ := primitive.ObjectIDFromHex(hex)
+	if oid != expected {
+		t.Errorf("Expected ObjectID %s, got %s", expected.Hex(), oid.Hex())
+	}
+}
+
+// testSchemaThenOptimizePreservesCoercion tests that a field coerced by a
+// Schema stays coerced after Optimize merges its bounds into a RangeNode,
+// instead of reverting to the raw string literal.
+func testSchemaThenOptimizePreservesCoercion(t *testing.T) {
+	ast, err := Parse("age==gt==10;age==lt==20")
+	if err != nil {
+		t.Fatalf("Error parsing RSQL query: %s", err)
+	}
+	schemaApplied, err := testSchema().apply(ast)
+	if err != nil {
+		t.Fatalf("Error applying schema: %s", err)
+	}
+	optimized, err := Optimize(schemaApplied)
+	if err != nil {
+		t.Fatalf("Error optimizing RSQL query: %s", err)
+	}
+	query, err := ConvertToMongo(optimized)
+	if err != nil {
+		t.Fatalf("Error converting to Mongo: %s", err)
+	}
+	bounds, ok := query["age"].(bson.M)
+	if !ok {
+		t.Fatalf("Expected a filter on 'age', got %v", query)
+	}
+	if _, ok := bounds["$gt"].(int); !ok {
+		t.Errorf("Expected a coerced int $gt, got %T (%v)", bounds["$gt"], bounds["$gt"])
+	}
+	if _, ok := bounds["$lt"].(int); !ok {
+		t.Errorf("Expected a coerced int $lt, got %T (%v)", bounds["$lt"], bounds["$lt"])
+	}
+}
+
+// testOptimizeThenSchemaValidatesRangeNode tests that running Optimize
+// before the Schema still validates and translates a merged RangeNode
+// like it would a lone ComparisonNode: unknown fields are rejected,
+// disallowed operators are rejected, and known fields are translated to
+// their db name.
+func testOptimizeThenSchemaValidatesRangeNode(t *testing.T) {
+	rangeNodeFor := func(query string) *RangeNode {
+		ast, err := Parse(query)
+		if err != nil {
+			t.Fatalf("Error parsing RSQL query: %s", err)
+		}
+		optimized, err := Optimize(ast)
+		if err != nil {
+			t.Fatalf("Error optimizing RSQL query: %s", err)
+		}
+		rng, ok := optimized.(*RangeNode)
+		if !ok {
+			t.Fatalf("Expected a RangeNode, got %T", optimized)
+		}
+		return rng
+	}
+
+	t.Run("TranslatesFieldName", func(t *testing.T) {
+		s := NewSchema()
+		s.RegisterField("age", "user_age", Int)
+		result, err := s.apply(rangeNodeFor("age==gt==10;age==lt==20"))
+		if err != nil {
+			t.Fatalf("Error applying schema: %s", err)
+		}
+		rng := result.(*RangeNode)
+		if rng.Field != "user_age" {
+			t.Errorf("Expected the range's field to be translated to 'user_age', got %q", rng.Field)
+		}
+		if _, ok := rng.Min.Coerced.(int); !ok {
+			t.Errorf("Expected the range's Min bound to be coerced to an int, got %T (%v)", rng.Min.Coerced, rng.Min.Coerced)
+		}
+		if _, ok := rng.Max.Coerced.(int); !ok {
+			t.Errorf("Expected the range's Max bound to be coerced to an int, got %T (%v)", rng.Max.Coerced, rng.Max.Coerced)
+		}
+	})
+
+	t.Run("RejectsUnknownField", func(t *testing.T) {
+		_, err := testSchema().apply(rangeNodeFor("secret==gt==10;secret==lt==20"))
+		if !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+	})
+
+	t.Run("RejectsDisallowedOperator", func(t *testing.T) {
+		s := NewSchema()
+		s.RegisterField("name", "full_name", String)
+		_, err := s.apply(rangeNodeFor("name==gt==10;name==lt==20"))
+		if !errors.Is(err, ErrOperatorNotAllowed) {
+			t.Errorf("Expected ErrOperatorNotAllowed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package go_rsql_parser
+
+import (
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// sqlIdentifierField checks that a field name is safe to embed directly
+// into SQL text. squirrel interpolates map keys (e.g. squirrel.Eq{field:
+// value}) straight into the generated predicate without escaping them,
+// so an RSQL field name containing arbitrary characters is a SQL
+// injection primitive. A Schema normally maps RSQL field names to
+// trusted db columns before this point, but toSquirrel is reachable
+// without one (via Compile/ParseRSQL with no WithSchema option), so it
+// must refuse anything that isn't a plain identifier itself.
+func sqlIdentifierField(field string) bool {
+	if field == "" {
+		return false
+	}
+	for i := 0; i < len(field); i++ {
+		c := field[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// sqlDialect compiles an RSQL AST into a squirrel.Sqlizer, suitable for
+// embedding in a squirrel SelectBuilder's Where clause.
+type sqlDialect struct{}
+
+func (sqlDialect) Compile(ast Node) (any, []any, error) {
+	sqlizer, err := toSquirrel(ast)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, args, err := sqlizer.ToSql()
+	if err != nil {
+		return nil, nil, err
+	}
+	return sqlizer, args, nil
+}
+
+// toSquirrel walks an RSQL AST, translating it into a squirrel.Sqlizer.
+// AndNode/OrNode chains are flattened into a single squirrel.And/Or so
+// that chained conjunctions/disjunctions produce one clause instead of
+// nested pairs.
+func toSquirrel(n Node) (squirrel.Sqlizer, error) {
+	switch v := n.(type) {
+	case *ComparisonNode:
+		if !sqlIdentifierField(v.Field) {
+			return nil, errors.New("rsql: field '" + v.Field + "' is not a valid SQL identifier")
+		}
+		return comparisonToSquirrel(v)
+	case *RangeNode:
+		if !sqlIdentifierField(v.Field) {
+			return nil, errors.New("rsql: field '" + v.Field + "' is not a valid SQL identifier")
+		}
+		return rangeToSquirrel(v), nil
+	case *MatchNone:
+		return squirrel.Expr("1 = 0"), nil
+	case *AndNode:
+		conjuncts, err := toSquirrelAll(flattenAnd(v))
+		if err != nil {
+			return nil, err
+		}
+		return squirrel.And(conjuncts), nil
+	case *OrNode:
+		disjuncts, err := toSquirrelAll(flattenOr(v))
+		if err != nil {
+			return nil, err
+		}
+		return squirrel.Or(disjuncts), nil
+	default:
+		return nil, errors.New("rsql: unsupported AST node")
+	}
+}
+
+func toSquirrelAll(nodes []Node) ([]squirrel.Sqlizer, error) {
+	sqlizers := make([]squirrel.Sqlizer, 0, len(nodes))
+	for _, node := range nodes {
+		sqlizer, err := toSquirrel(node)
+		if err != nil {
+			return nil, err
+		}
+		sqlizers = append(sqlizers, sqlizer)
+	}
+	return sqlizers, nil
+}
+
+// rangeToSquirrel converts a RangeNode into a conjunction of its lower
+// and upper bound predicates. Bound values are rendered via boundValue,
+// the same Schema-coerced-or-raw-string representation a lone
+// (unmerged) gt/ge/lt/le ComparisonNode carries, so a predicate's arg
+// type doesn't depend on whether two bounds happened to merge.
+func rangeToSquirrel(r *RangeNode) squirrel.Sqlizer {
+	var conjuncts []squirrel.Sqlizer
+	if r.Min != nil {
+		if r.Min.Inclusive {
+			conjuncts = append(conjuncts, squirrel.GtOrEq{r.Field: boundValue(r.Min)})
+		} else {
+			conjuncts = append(conjuncts, squirrel.Gt{r.Field: boundValue(r.Min)})
+		}
+	}
+	if r.Max != nil {
+		if r.Max.Inclusive {
+			conjuncts = append(conjuncts, squirrel.LtOrEq{r.Field: boundValue(r.Max)})
+		} else {
+			conjuncts = append(conjuncts, squirrel.Lt{r.Field: boundValue(r.Max)})
+		}
+	}
+	return squirrel.And(conjuncts)
+}
+
+// comparisonToSquirrel converts a single ComparisonNode into a squirrel
+// predicate.
+func comparisonToSquirrel(c *ComparisonNode) (squirrel.Sqlizer, error) {
+	switch c.Op {
+	case "==", "eq":
+		return squirrel.Eq{c.Field: c.value()}, nil
+	case "ne":
+		return squirrel.NotEq{c.Field: c.value()}, nil
+	case "gt":
+		return squirrel.Gt{c.Field: c.value()}, nil
+	case "ge":
+		return squirrel.GtOrEq{c.Field: c.value()}, nil
+	case "lt":
+		return squirrel.Lt{c.Field: c.value()}, nil
+	case "le":
+		return squirrel.LtOrEq{c.Field: c.value()}, nil
+	case "in":
+		return squirrel.Eq{c.Field: c.values()}, nil
+	case "out":
+		return squirrel.NotEq{c.Field: c.values()}, nil
+	case "like":
+		return squirrel.Like{c.Field: "%" + stringValue(c) + "%"}, nil
+	case "ilike":
+		return squirrel.ILike{c.Field: "%" + stringValue(c) + "%"}, nil
+	default:
+		return nil, errors.New("invalid operator: " + c.Op)
+	}
+}